@@ -0,0 +1,22 @@
+package errors
+
+import "fmt"
+
+type boshError struct {
+	msg string
+}
+
+func (e boshError) Error() string {
+	return e.msg
+}
+
+// New builds an error from a format string, in the style of fmt.Errorf.
+func New(msg string, args ...interface{}) error {
+	return boshError{msg: fmt.Sprintf(msg, args...)}
+}
+
+// WrapError annotates cause with additional context, keeping cause's message
+// visible in the result.
+func WrapError(cause error, msg string) error {
+	return boshError{msg: fmt.Sprintf("%s: %s", msg, cause.Error())}
+}
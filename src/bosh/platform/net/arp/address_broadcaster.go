@@ -0,0 +1,15 @@
+package arp
+
+// AddressPair associates an interface with the IP address that should be
+// broadcast on its behalf.
+type AddressPair struct {
+	IP        string
+	Interface string
+}
+
+// AddressBroadcaster announces a set of (interface, IP) pairs to the local
+// network segment so that switches and neighboring ARP caches pick up a
+// freshly configured address without waiting for their own timeouts.
+type AddressBroadcaster interface {
+	BroadcastMACAddresses(addresses []AddressPair)
+}
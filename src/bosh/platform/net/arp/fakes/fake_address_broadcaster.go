@@ -0,0 +1,15 @@
+package fakes
+
+import "bosh/platform/net/arp"
+
+type FakeAddressBroadcaster struct {
+	BroadcastedAddresses []arp.AddressPair
+}
+
+func NewFakeAddressBroadcaster() *FakeAddressBroadcaster {
+	return &FakeAddressBroadcaster{}
+}
+
+func (b *FakeAddressBroadcaster) BroadcastMACAddresses(addresses []arp.AddressPair) {
+	b.BroadcastedAddresses = addresses
+}
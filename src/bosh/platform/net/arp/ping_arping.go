@@ -0,0 +1,31 @@
+package arp
+
+import (
+	"time"
+
+	boshsys "bosh/system"
+)
+
+const arpBroadcastAttempts = 6
+
+type pingArping struct {
+	cmdRunner       boshsys.CmdRunner
+	arpWaitInterval time.Duration
+}
+
+// NewPingArping returns an AddressBroadcaster that shells out to `arping` to
+// send unsolicited (gratuitous) ARP requests for each address.
+func NewPingArping(cmdRunner boshsys.CmdRunner, arpWaitInterval time.Duration) (broadcaster pingArping) {
+	broadcaster.cmdRunner = cmdRunner
+	broadcaster.arpWaitInterval = arpWaitInterval
+	return
+}
+
+func (broadcaster pingArping) BroadcastMACAddresses(addresses []AddressPair) {
+	for i := 0; i < arpBroadcastAttempts; i++ {
+		for _, address := range addresses {
+			broadcaster.cmdRunner.RunCommand("arping", "-c", "1", "-U", "-I", address.Interface, address.IP)
+			time.Sleep(broadcaster.arpWaitInterval)
+		}
+	}
+}
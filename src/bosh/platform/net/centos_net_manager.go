@@ -0,0 +1,189 @@
+package net
+
+import (
+	"fmt"
+
+	bosherr "bosh/errors"
+	"bosh/platform/net/arp"
+	"bosh/platform/net/ip"
+	boshsettings "bosh/settings"
+	boshsys "bosh/system"
+)
+
+type centos struct {
+	cmdRunner          boshsys.CmdRunner
+	fs                 boshsys.FileSystem
+	ipResolver         ip.IPResolver
+	addressBroadcaster arp.AddressBroadcaster
+}
+
+func NewCentosNetManager(
+	fs boshsys.FileSystem,
+	cmdRunner boshsys.CmdRunner,
+	ipResolver ip.IPResolver,
+	addressBroadcaster arp.AddressBroadcaster,
+) (net centos) {
+	net.cmdRunner = cmdRunner
+	net.fs = fs
+	net.ipResolver = ipResolver
+	net.addressBroadcaster = addressBroadcaster
+	return
+}
+
+func (net centos) SetupDhcp(networks boshsettings.Networks) error {
+	dnsServersArg := dnsConfigArg{DNSServers: getDNSServers(networks)}
+
+	buffer, err := renderTemplate("dhcp-config", centosDHCPConfigTemplate, dnsServersArg)
+	if err != nil {
+		return err
+	}
+
+	written, err := convergeFile(net.fs, "/etc/dhcp/dhclient.conf", buffer.Bytes())
+	if err != nil {
+		return err
+	}
+
+	if written {
+		// Ignore errors here, just run the commands
+		net.cmdRunner.RunCommand("service", "network", "restart")
+	}
+
+	return nil
+}
+
+// DHCP Config file - /etc/dhcp/dhclient.conf
+const centosDHCPConfigTemplate = `# Generated by bosh-agent
+
+option rfc3442-classless-static-routes code 121 = array of unsigned integer 8;
+
+send host-name "<hostname>";
+
+request subnet-mask, broadcast-address, time-offset, routers,
+	domain-name, domain-name-servers, domain-search, host-name,
+	netbios-name-servers, netbios-scope, interface-mtu,
+	rfc3442-classless-static-routes, ntp-servers;
+
+{{ range .DNSServers }}prepend domain-name-servers {{ . }};
+{{ end }}`
+
+func (net centos) SetupManualNetworking(networks boshsettings.Networks) error {
+	macAddresses, err := detectMacAddresses(net.fs)
+	if err != nil {
+		return bosherr.WrapError(err, "Detecting mac addresses")
+	}
+
+	snapshot := snapshotNetworkState(net.fs, centosConfigPaths(networks, macAddresses))
+
+	modifiedNetworks, written, err := net.writeNetworkInterfaces(networks, macAddresses)
+	if err != nil {
+		return bosherr.WrapError(err, "Writing network interfaces")
+	}
+
+	err = writeResolvConf(net.fs, networks)
+	if err != nil {
+		return bosherr.WrapError(err, "Writing resolv.conf")
+	}
+
+	if written {
+		err = convergeNetworkInterfaces(
+			net.fs,
+			snapshot,
+			net.ipResolver,
+			modifiedNetworks,
+			net.restartNetworkingInterfaces,
+		)
+		if err != nil {
+			return bosherr.WrapError(err, "Converging network interfaces")
+		}
+	}
+
+	go broadcastMACAddresses(net.ipResolver, net.addressBroadcaster, modifiedNetworks)
+
+	return nil
+}
+
+const centosNetworkConfigPath = "/etc/sysconfig/network"
+
+func centosIfcfgPath(iface string) string {
+	return fmt.Sprintf("/etc/sysconfig/network-scripts/ifcfg-%s", iface)
+}
+
+// centosConfigPaths lists every config file SetupManualNetworking writes, so
+// it can be snapshotted before being overwritten: the shared network file,
+// plus one ifcfg-<iface> file per network.
+func centosConfigPaths(networks boshsettings.Networks, macAddresses map[string]string) []string {
+	paths := []string{centosNetworkConfigPath}
+	for _, aNet := range networks {
+		paths = append(paths, centosIfcfgPath(macAddresses[aNet.Mac]))
+	}
+	return paths
+}
+
+func (net centos) writeNetworkInterfaces(networks boshsettings.Networks, macAddresses map[string]string) ([]CustomNetwork, bool, error) {
+	var modifiedNetworks []CustomNetwork
+
+	anyWritten := false
+
+	for _, aNet := range networks {
+		network, broadcast, err := boshsys.CalculateNetworkAndBroadcast(aNet.IP, aNet.Netmask)
+		if err != nil {
+			return modifiedNetworks, false, bosherr.WrapError(err, "Calculating network and broadcast")
+		}
+
+		newNet := CustomNetwork{
+			Network:           aNet,
+			Interface:         macAddresses[aNet.Mac],
+			NetworkIP:         network,
+			Broadcast:         broadcast,
+			HasDefaultGateway: true,
+		}
+		modifiedNetworks = append(modifiedNetworks, newNet)
+
+		buffer, err := renderTemplate("ifcfg", centosIfcfgTemplate, newNet)
+		if err != nil {
+			return modifiedNetworks, false, err
+		}
+
+		written, err := convergeFile(net.fs, centosIfcfgPath(newNet.Interface), buffer.Bytes())
+		if err != nil {
+			return modifiedNetworks, false, err
+		}
+
+		anyWritten = anyWritten || written
+	}
+
+	networkBuffer, err := renderTemplate("network", centosNetworkTemplate, modifiedNetworks)
+	if err != nil {
+		return modifiedNetworks, false, err
+	}
+
+	written, err := convergeFile(net.fs, centosNetworkConfigPath, networkBuffer.Bytes())
+	if err != nil {
+		return modifiedNetworks, false, err
+	}
+
+	anyWritten = anyWritten || written
+
+	return modifiedNetworks, anyWritten, nil
+}
+
+const centosIfcfgTemplate = `DEVICE={{ .Interface }}
+BOOTPROTO=static
+ONBOOT=yes
+IPADDR={{ .IP }}
+NETMASK={{ .Netmask }}
+NETWORK={{ .NetworkIP }}
+BROADCAST={{ .Broadcast }}
+{{ if .HasDefaultGateway }}GATEWAY={{ .Gateway }}
+{{ end }}`
+
+const centosNetworkTemplate = `NETWORKING=yes
+{{ range . }}{{ if .HasDefaultGateway }}GATEWAY={{ .Gateway }}
+{{ end }}{{ end }}`
+
+func (net centos) restartNetworkingInterfaces(networks []CustomNetwork) {
+	for _, network := range networks {
+		net.cmdRunner.RunCommand("ifdown", network.Interface)
+		net.cmdRunner.RunCommand("ifup", network.Interface)
+	}
+}
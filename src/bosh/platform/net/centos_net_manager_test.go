@@ -0,0 +1,67 @@
+package net
+
+import (
+	"testing"
+
+	boshsettings "bosh/settings"
+	boshsysfakes "bosh/system/fakes"
+)
+
+func TestCentosWriteNetworkInterfacesWritesPerInterfaceIfcfgAndNetworkFiles(t *testing.T) {
+	fs := boshsysfakes.NewFakeFileSystem()
+	cmdRunner := boshsysfakes.NewFakeCmdRunner()
+	net := NewCentosNetManager(fs, cmdRunner, nil, nil)
+
+	networks := boshsettings.Networks{
+		{IP: "10.0.0.5", Netmask: "255.255.255.0", Gateway: "10.0.0.1", Mac: "aa:bb:cc:dd:ee:ff"},
+	}
+	macAddresses := map[string]string{"aa:bb:cc:dd:ee:ff": "eth0"}
+
+	modifiedNetworks, written, err := net.writeNetworkInterfaces(networks, macAddresses)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+	if !written {
+		t.Fatal("expected writeNetworkInterfaces to report changes written")
+	}
+	if len(modifiedNetworks) != 1 || modifiedNetworks[0].Interface != "eth0" {
+		t.Fatalf("expected modified network for eth0, got %+v", modifiedNetworks)
+	}
+
+	ifcfg := fs.GetFileContents("/etc/sysconfig/network-scripts/ifcfg-eth0")
+	if ifcfg != "DEVICE=eth0\nBOOTPROTO=static\nONBOOT=yes\nIPADDR=10.0.0.5\nNETMASK=255.255.255.0\nNETWORK=10.0.0.0\nBROADCAST=10.0.0.255\nGATEWAY=10.0.0.1\n" {
+		t.Errorf("unexpected ifcfg-eth0 contents: %q", ifcfg)
+	}
+
+	network := fs.GetFileContents("/etc/sysconfig/network")
+	if network != "NETWORKING=yes\nGATEWAY=10.0.0.1\n" {
+		t.Errorf("unexpected /etc/sysconfig/network contents: %q", network)
+	}
+}
+
+func TestCentosConfigPathsIncludesNetworkFileAndEveryIfcfgFile(t *testing.T) {
+	networks := boshsettings.Networks{
+		{Mac: "aa:bb:cc:dd:ee:ff"},
+		{Mac: "11:22:33:44:55:66"},
+	}
+	macAddresses := map[string]string{
+		"aa:bb:cc:dd:ee:ff": "eth0",
+		"11:22:33:44:55:66": "eth1",
+	}
+
+	paths := centosConfigPaths(networks, macAddresses)
+
+	expected := []string{
+		"/etc/sysconfig/network",
+		"/etc/sysconfig/network-scripts/ifcfg-eth0",
+		"/etc/sysconfig/network-scripts/ifcfg-eth1",
+	}
+	if len(paths) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, paths)
+	}
+	for i, path := range expected {
+		if paths[i] != path {
+			t.Errorf("expected paths[%d] to be %s, got %s", i, path, paths[i])
+		}
+	}
+}
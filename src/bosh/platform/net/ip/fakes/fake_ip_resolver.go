@@ -0,0 +1,34 @@
+package fakes
+
+import "time"
+
+type FakeIPResolver struct {
+	GetPrimaryIPv4IPs map[string]string
+	GetPrimaryIPv4Err error
+
+	// VerifyResults/VerifyErrs are keyed by interface name, so different
+	// interfaces can be made to converge or fail independently.
+	VerifyResults map[string]bool
+	VerifyErrs    map[string]error
+
+	VerifyInterfaces []string
+	VerifyIPs        []string
+}
+
+func NewFakeIPResolver() *FakeIPResolver {
+	return &FakeIPResolver{
+		GetPrimaryIPv4IPs: map[string]string{},
+		VerifyResults:     map[string]bool{},
+		VerifyErrs:        map[string]error{},
+	}
+}
+
+func (r *FakeIPResolver) GetPrimaryIPv4(interfaceName string) (string, error) {
+	return r.GetPrimaryIPv4IPs[interfaceName], r.GetPrimaryIPv4Err
+}
+
+func (r *FakeIPResolver) Verify(interfaceName string, expectedIP string, timeout time.Duration) (bool, error) {
+	r.VerifyInterfaces = append(r.VerifyInterfaces, interfaceName)
+	r.VerifyIPs = append(r.VerifyIPs, expectedIP)
+	return r.VerifyResults[interfaceName], r.VerifyErrs[interfaceName]
+}
@@ -0,0 +1,73 @@
+package ip
+
+import (
+	"path/filepath"
+	"regexp"
+	"time"
+
+	bosherr "bosh/errors"
+	boshsys "bosh/system"
+)
+
+// IPResolver determines the IPv4 address currently held by a network
+// interface, so that callers can confirm a configuration change has actually
+// taken effect before acting on it (e.g. broadcasting ARP).
+type IPResolver interface {
+	GetPrimaryIPv4(interfaceName string) (string, error)
+
+	// Verify polls interfaceName until it holds expectedIP or timeout elapses,
+	// returning whether it converged.
+	Verify(interfaceName string, expectedIP string, timeout time.Duration) (bool, error)
+}
+
+const verifyPollInterval = 250 * time.Millisecond
+
+var ipv4AddrRegexp = regexp.MustCompile(`inet (\d+\.\d+\.\d+\.\d+)`)
+
+type interfaceResolver struct {
+	fs        boshsys.FileSystem
+	cmdRunner boshsys.CmdRunner
+}
+
+// NewResolver returns an IPResolver that reads interface state via
+// /sys/class/net and `ip addr show`.
+func NewResolver(fs boshsys.FileSystem, cmdRunner boshsys.CmdRunner) (resolver interfaceResolver) {
+	resolver.fs = fs
+	resolver.cmdRunner = cmdRunner
+	return
+}
+
+func (r interfaceResolver) GetPrimaryIPv4(interfaceName string) (string, error) {
+	if !r.fs.FileExists(filepath.Join("/sys/class/net", interfaceName)) {
+		return "", bosherr.New("Interface %s is not present", interfaceName)
+	}
+
+	stdout, _, _, err := r.cmdRunner.RunCommand("ip", "addr", "show", interfaceName)
+	if err != nil {
+		return "", bosherr.WrapError(err, "Running ip addr show")
+	}
+
+	matches := ipv4AddrRegexp.FindStringSubmatch(stdout)
+	if matches == nil {
+		return "", bosherr.New("Interface %s has not acquired an IPv4 address", interfaceName)
+	}
+
+	return matches[1], nil
+}
+
+func (r interfaceResolver) Verify(interfaceName string, expectedIP string, timeout time.Duration) (bool, error) {
+	deadline := time.Now().Add(timeout)
+
+	for {
+		actualIP, err := r.GetPrimaryIPv4(interfaceName)
+		if err == nil && actualIP == expectedIP {
+			return true, nil
+		}
+
+		if time.Now().After(deadline) {
+			return false, nil
+		}
+
+		time.Sleep(verifyPollInterval)
+	}
+}
@@ -0,0 +1,32 @@
+package net
+
+import "testing"
+
+func TestSplitIPv6CIDR(t *testing.T) {
+	address, prefixLen, err := splitIPv6CIDR("2001:db8::2/64")
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if address != "2001:db8::2" || prefixLen != "64" {
+		t.Errorf("expected 2001:db8::2/64, got %s/%s", address, prefixLen)
+	}
+}
+
+func TestSplitIPv6CIDRInvalid(t *testing.T) {
+	_, _, err := splitIPv6CIDR("2001:db8::2")
+	if err == nil {
+		t.Fatal("expected an error for a CIDR with no prefix length")
+	}
+}
+
+func TestCalculateIPv6Network(t *testing.T) {
+	network, err := calculateIPv6Network("2001:db8::2", "64")
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if network != "2001:db8::" {
+		t.Errorf("expected network 2001:db8::, got %s", network)
+	}
+}
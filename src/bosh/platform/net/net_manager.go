@@ -0,0 +1,304 @@
+package net
+
+import (
+	"bytes"
+	"fmt"
+	stdnet "net"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	bosherr "bosh/errors"
+	"bosh/platform/net/arp"
+	"bosh/platform/net/ip"
+	boshsettings "bosh/settings"
+	boshsys "bosh/system"
+)
+
+// NetManager represents a platform-specific strategy for configuring DHCP and
+// manual (static) networking.
+type NetManager interface {
+	SetupDhcp(networks boshsettings.Networks) error
+	SetupManualNetworking(networks boshsettings.Networks) error
+}
+
+// CustomNetwork augments a settings Network with information discovered at
+// converge time (which interface it maps to, and its computed network/broadcast
+// addresses).
+type CustomNetwork struct {
+	boshsettings.Network
+	Interface         string
+	NetworkIP         string
+	Broadcast         string
+	HasDefaultGateway bool
+
+	// PrefixLength is only populated by platforms that configure addresses via
+	// CIDR prefix length rather than a dotted netmask (e.g. Windows).
+	PrefixLength string
+
+	// IPv6 fields are only populated when the underlying Network carries an IP6 CIDR.
+	HasIPv6            bool
+	IPv6Address        string
+	IPv6Prefix         string
+	IPv6NetworkIP      string
+	HasDefaultGateway6 bool
+}
+
+type dnsConfigArg struct {
+	DNSServers []string
+	HasIPv6    bool
+}
+
+func hasIPv6Network(networks boshsettings.Networks) bool {
+	for _, network := range networks {
+		if network.IP6 != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// splitIPv6CIDR splits an "address/prefix" CIDR string (e.g. "2001:db8::2/64")
+// into its address and prefix length.
+func splitIPv6CIDR(cidr string) (address string, prefixLen string, err error) {
+	parts := strings.SplitN(cidr, "/", 2)
+	if len(parts) != 2 {
+		return "", "", bosherr.New("Invalid IPv6 CIDR %s", cidr)
+	}
+	return parts[0], parts[1], nil
+}
+
+// calculateIPv6Network derives the network address for an IPv6 address/prefix
+// pair. IPv6 has no broadcast address and expresses its prefix as a bit
+// length rather than a dotted netmask, so this isn't a job for
+// boshsys.CalculateNetworkAndBroadcast (which is IPv4-only) - it's computed
+// locally instead.
+func calculateIPv6Network(address string, prefixLen string) (string, error) {
+	_, ipNet, err := stdnet.ParseCIDR(address + "/" + prefixLen)
+	if err != nil {
+		return "", bosherr.WrapError(err, fmt.Sprintf("Parsing IPv6 CIDR %s/%s", address, prefixLen))
+	}
+	return ipNet.IP.String(), nil
+}
+
+// NewNetManager returns the NetManager for the given operating system name,
+// e.g. as reported by the infrastructure's stemcell metadata.
+func NewNetManager(
+	fs boshsys.FileSystem,
+	cmdRunner boshsys.CmdRunner,
+	arpWaitInterval time.Duration,
+	osName string,
+) (NetManager, error) {
+	ipResolver := ip.NewResolver(fs, cmdRunner)
+	addressBroadcaster := arp.NewPingArping(cmdRunner, arpWaitInterval)
+
+	switch osName {
+	case "ubuntu":
+		return NewUbuntuNetManager(fs, cmdRunner, ipResolver, addressBroadcaster), nil
+	case "centos":
+		return NewCentosNetManager(fs, cmdRunner, ipResolver, addressBroadcaster), nil
+	default:
+		return nil, bosherr.New("Unknown net manager for OS %s", osName)
+	}
+}
+
+func getDNSServers(networks boshsettings.Networks) []string {
+	var dnsServers []string
+	dnsNetwork, found := networks.DefaultNetworkFor("dns")
+	if found {
+		for i := len(dnsNetwork.DNS) - 1; i >= 0; i-- {
+			dnsServers = append(dnsServers, dnsNetwork.DNS[i])
+		}
+	}
+	return dnsServers
+}
+
+func writeResolvConf(fs boshsys.FileSystem, networks boshsettings.Networks) error {
+	dnsServersArg := dnsConfigArg{DNSServers: getDNSServers(networks)}
+
+	buffer, err := renderTemplate("resolv-conf", resolvConfTemplate, dnsServersArg)
+	if err != nil {
+		return err
+	}
+
+	err = fs.WriteFile("/etc/resolv.conf", buffer.Bytes())
+	if err != nil {
+		return bosherr.WrapError(err, "Writing to /etc/resolv.conf")
+	}
+
+	return nil
+}
+
+const resolvConfTemplate = `# Generated by bosh-agent
+{{ range .DNSServers }}nameserver {{ . }}
+{{ end }}`
+
+// renderTemplate executes a text/template against data and returns the
+// rendered bytes, wrapping parse/execute failures the same way across all
+// NetManager implementations.
+func renderTemplate(name string, tmplStr string, data interface{}) (*bytes.Buffer, error) {
+	buffer := bytes.NewBuffer([]byte{})
+	t := template.Must(template.New(name).Parse(tmplStr))
+
+	err := t.Execute(buffer, data)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Generating config from template")
+	}
+
+	return buffer, nil
+}
+
+// convergeFile writes contents to path only if they differ from what's
+// already there, so callers can tell whether a restart is actually needed.
+func convergeFile(fs boshsys.FileSystem, path string, contents []byte) (bool, error) {
+	written, err := fs.ConvergeFileContents(path, contents)
+	if err != nil {
+		return false, bosherr.WrapError(err, fmt.Sprintf("Writing to %s", path))
+	}
+
+	return written, nil
+}
+
+func detectMacAddresses(fs boshsys.FileSystem) (map[string]string, error) {
+	addresses := map[string]string{}
+
+	filePaths, err := fs.Glob("/sys/class/net/*")
+	if err != nil {
+		return addresses, bosherr.WrapError(err, "Getting file list from /sys/class/net")
+	}
+
+	var macAddress string
+	for _, filePath := range filePaths {
+		macAddress, err = fs.ReadFileString(filepath.Join(filePath, "address"))
+		if err != nil {
+			return addresses, bosherr.WrapError(err, "Reading mac address from file")
+		}
+
+		macAddress = strings.Trim(macAddress, "\n")
+
+		interfaceName := filepath.Base(filePath)
+		addresses[macAddress] = interfaceName
+	}
+
+	return addresses, nil
+}
+
+const ipResolveTimeout = 10 * time.Second
+
+// broadcastMACAddresses waits for each network's interface to actually hold
+// its newly configured IP (polling, since the interface may still be
+// converging after a restart) and then hands the confirmed (interface, IP)
+// pairs off to the broadcaster. Verifying against the configured IP, rather
+// than accepting whatever address GetPrimaryIPv4 first returns, matters on
+// reconfiguration: the interface can still be carrying its previous address
+// at the moment of the first poll, and broadcasting that stale address would
+// defeat the point of a gratuitous ARP. This runs in the background, so a
+// network that never converges is simply skipped rather than failing the
+// caller.
+func broadcastMACAddresses(
+	ipResolver ip.IPResolver,
+	addressBroadcaster arp.AddressBroadcaster,
+	networks []CustomNetwork,
+) {
+	var addresses []arp.AddressPair
+
+	for _, network := range networks {
+		verified, err := ipResolver.Verify(network.Interface, network.IP, ipResolveTimeout)
+		if err != nil || !verified {
+			continue
+		}
+
+		addresses = append(addresses, arp.AddressPair{IP: network.IP, Interface: network.Interface})
+	}
+
+	addressBroadcaster.BroadcastMACAddresses(addresses)
+}
+
+const convergeVerifyTimeout = 10 * time.Second
+
+// networkSnapshot captures enough of the previous networking state to restore
+// it if a reconfiguration doesn't converge. configFiles holds the prior
+// contents of every config file a platform's SetupManualNetworking is about
+// to (re)write - e.g. Ubuntu's single /etc/network/interfaces, or CentOS's
+// /etc/sysconfig/network plus one ifcfg-<iface> file per interface.
+type networkSnapshot struct {
+	configFiles map[string]string
+	resolvConf  string
+}
+
+func snapshotNetworkState(fs boshsys.FileSystem, configPaths []string) networkSnapshot {
+	snapshot := networkSnapshot{configFiles: map[string]string{}}
+
+	for _, path := range configPaths {
+		snapshot.configFiles[path], _ = fs.ReadFileString(path)
+	}
+	snapshot.resolvConf, _ = fs.ReadFileString("/etc/resolv.conf")
+
+	return snapshot
+}
+
+func (snapshot networkSnapshot) restore(fs boshsys.FileSystem) error {
+	for path, contents := range snapshot.configFiles {
+		err := fs.WriteFile(path, []byte(contents))
+		if err != nil {
+			return bosherr.WrapError(err, fmt.Sprintf("Restoring %s", path))
+		}
+	}
+
+	err := fs.WriteFile("/etc/resolv.conf", []byte(snapshot.resolvConf))
+	if err != nil {
+		return bosherr.WrapError(err, "Restoring /etc/resolv.conf")
+	}
+
+	return nil
+}
+
+// convergeNetworkInterfaces restarts networking and verifies that every
+// modified interface actually acquired its configured address within a
+// timeout. If any interface fails to converge, it restores the
+// pre-reconfiguration snapshot, restarts again, and returns an error -
+// leaving the VM on its last-known-good network configuration rather than
+// stuck half-reconfigured and unreachable.
+func convergeNetworkInterfaces(
+	fs boshsys.FileSystem,
+	snapshot networkSnapshot,
+	ipResolver ip.IPResolver,
+	modifiedNetworks []CustomNetwork,
+	restart func([]CustomNetwork),
+) error {
+	restart(modifiedNetworks)
+
+	for _, network := range modifiedNetworks {
+		verified, err := ipResolver.Verify(network.Interface, network.IP, convergeVerifyTimeout)
+
+		if err != nil || !verified {
+			restoreErr := snapshot.restore(fs)
+			if restoreErr != nil {
+				return bosherr.WrapError(restoreErr, fmt.Sprintf(
+					"Restoring previous network config after interface %s failed to converge to %s",
+					network.Interface,
+					network.IP,
+				))
+			}
+
+			restart(modifiedNetworks)
+
+			if err != nil {
+				return bosherr.WrapError(err, fmt.Sprintf(
+					"Verifying interface %s; restored previous network configuration",
+					network.Interface,
+				))
+			}
+
+			return bosherr.New(
+				"Interface %s did not converge to %s within %s; restored previous network configuration",
+				network.Interface,
+				network.IP,
+				convergeVerifyTimeout,
+			)
+		}
+	}
+
+	return nil
+}
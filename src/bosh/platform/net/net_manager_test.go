@@ -0,0 +1,158 @@
+package net
+
+import (
+	"testing"
+
+	arpfakes "bosh/platform/net/arp/fakes"
+	ipfakes "bosh/platform/net/ip/fakes"
+	boshsettings "bosh/settings"
+	boshsysfakes "bosh/system/fakes"
+)
+
+func TestBroadcastMACAddressesOnlyBroadcastsConvergedNetworks(t *testing.T) {
+	ipResolver := ipfakes.NewFakeIPResolver()
+	ipResolver.VerifyResults["eth0"] = true
+	ipResolver.VerifyResults["eth1"] = false
+
+	broadcaster := arpfakes.NewFakeAddressBroadcaster()
+
+	networks := []CustomNetwork{
+		{Network: boshsettings.Network{IP: "10.0.0.5"}, Interface: "eth0"},
+		{Network: boshsettings.Network{IP: "10.0.0.6"}, Interface: "eth1"},
+	}
+
+	broadcastMACAddresses(ipResolver, broadcaster, networks)
+
+	if len(broadcaster.BroadcastedAddresses) != 1 {
+		t.Fatalf("expected 1 broadcasted address, got %d", len(broadcaster.BroadcastedAddresses))
+	}
+
+	address := broadcaster.BroadcastedAddresses[0]
+	if address.Interface != "eth0" || address.IP != "10.0.0.5" {
+		t.Errorf("expected eth0/10.0.0.5 to be broadcast, got %+v", address)
+	}
+}
+
+func TestBroadcastMACAddressesVerifiesAgainstConfiguredIP(t *testing.T) {
+	ipResolver := ipfakes.NewFakeIPResolver()
+	ipResolver.VerifyResults["eth0"] = true
+
+	broadcaster := arpfakes.NewFakeAddressBroadcaster()
+
+	networks := []CustomNetwork{
+		{Network: boshsettings.Network{IP: "10.0.0.5"}, Interface: "eth0"},
+	}
+
+	broadcastMACAddresses(ipResolver, broadcaster, networks)
+
+	if len(ipResolver.VerifyInterfaces) != 1 || ipResolver.VerifyInterfaces[0] != "eth0" {
+		t.Fatalf("expected Verify to be called for eth0, got %v", ipResolver.VerifyInterfaces)
+	}
+
+	if len(ipResolver.VerifyIPs) != 1 || ipResolver.VerifyIPs[0] != "10.0.0.5" {
+		t.Fatalf("expected Verify to check the configured IP 10.0.0.5, got %v", ipResolver.VerifyIPs)
+	}
+}
+
+func TestConvergeNetworkInterfacesRestoresSnapshotWhenVerifyFails(t *testing.T) {
+	fs := boshsysfakes.NewFakeFileSystem()
+	fs.SetFileContents("/etc/network/interfaces", "previous config")
+	fs.SetFileContents("/etc/resolv.conf", "previous resolv.conf")
+
+	snapshot := snapshotNetworkState(fs, []string{"/etc/network/interfaces"})
+
+	fs.SetFileContents("/etc/network/interfaces", "new config")
+
+	ipResolver := ipfakes.NewFakeIPResolver()
+	ipResolver.VerifyResults["eth0"] = false
+
+	var restartCount int
+	restart := func(networks []CustomNetwork) {
+		restartCount++
+	}
+
+	networks := []CustomNetwork{
+		{Network: boshsettings.Network{IP: "10.0.0.5"}, Interface: "eth0"},
+	}
+
+	err := convergeNetworkInterfaces(fs, snapshot, ipResolver, networks, restart)
+	if err == nil {
+		t.Fatal("expected an error when the interface fails to converge")
+	}
+
+	if restartCount != 2 {
+		t.Errorf("expected restart to be called twice (initial + after rollback), got %d", restartCount)
+	}
+
+	if fs.GetFileContents("/etc/network/interfaces") != "previous config" {
+		t.Errorf("expected previous config to be restored, got %q", fs.GetFileContents("/etc/network/interfaces"))
+	}
+}
+
+func TestConvergeNetworkInterfacesRestoresEveryConfigFileInSnapshot(t *testing.T) {
+	fs := boshsysfakes.NewFakeFileSystem()
+	fs.SetFileContents("/etc/sysconfig/network", "previous network")
+	fs.SetFileContents("/etc/sysconfig/network-scripts/ifcfg-eth0", "previous ifcfg-eth0")
+
+	snapshot := snapshotNetworkState(fs, []string{
+		"/etc/sysconfig/network",
+		"/etc/sysconfig/network-scripts/ifcfg-eth0",
+	})
+
+	fs.SetFileContents("/etc/sysconfig/network", "new network")
+	fs.SetFileContents("/etc/sysconfig/network-scripts/ifcfg-eth0", "new ifcfg-eth0")
+
+	ipResolver := ipfakes.NewFakeIPResolver()
+	ipResolver.VerifyResults["eth0"] = false
+
+	networks := []CustomNetwork{
+		{Network: boshsettings.Network{IP: "10.0.0.5"}, Interface: "eth0"},
+	}
+
+	err := convergeNetworkInterfaces(fs, snapshot, ipResolver, networks, func([]CustomNetwork) {})
+	if err == nil {
+		t.Fatal("expected an error when the interface fails to converge")
+	}
+
+	if fs.GetFileContents("/etc/sysconfig/network") != "previous network" {
+		t.Errorf("expected /etc/sysconfig/network to be restored, got %q", fs.GetFileContents("/etc/sysconfig/network"))
+	}
+
+	if fs.GetFileContents("/etc/sysconfig/network-scripts/ifcfg-eth0") != "previous ifcfg-eth0" {
+		t.Errorf("expected ifcfg-eth0 to be restored, got %q", fs.GetFileContents("/etc/sysconfig/network-scripts/ifcfg-eth0"))
+	}
+}
+
+func TestConvergeNetworkInterfacesLeavesNewConfigWhenVerifySucceeds(t *testing.T) {
+	fs := boshsysfakes.NewFakeFileSystem()
+	fs.SetFileContents("/etc/network/interfaces", "previous config")
+
+	snapshot := snapshotNetworkState(fs, []string{"/etc/network/interfaces"})
+
+	fs.SetFileContents("/etc/network/interfaces", "new config")
+
+	ipResolver := ipfakes.NewFakeIPResolver()
+	ipResolver.VerifyResults["eth0"] = true
+
+	var restartCount int
+	restart := func(networks []CustomNetwork) {
+		restartCount++
+	}
+
+	networks := []CustomNetwork{
+		{Network: boshsettings.Network{IP: "10.0.0.5"}, Interface: "eth0"},
+	}
+
+	err := convergeNetworkInterfaces(fs, snapshot, ipResolver, networks, restart)
+	if err != nil {
+		t.Fatalf("expected no error when the interface converges, got %s", err.Error())
+	}
+
+	if restartCount != 1 {
+		t.Errorf("expected restart to be called once, got %d", restartCount)
+	}
+
+	if fs.GetFileContents("/etc/network/interfaces") != "new config" {
+		t.Errorf("expected new config to be left in place, got %q", fs.GetFileContents("/etc/network/interfaces"))
+	}
+}
@@ -1,59 +1,44 @@
 package net
 
 import (
-	"bytes"
-	"path/filepath"
-	"strings"
-	"text/template"
-	"time"
-
 	bosherr "bosh/errors"
+	"bosh/platform/net/arp"
+	"bosh/platform/net/ip"
 	boshsettings "bosh/settings"
 	boshsys "bosh/system"
 )
 
 type ubuntu struct {
-	arpWaitInterval time.Duration
-	cmdRunner       boshsys.CmdRunner
-	fs              boshsys.FileSystem
+	cmdRunner          boshsys.CmdRunner
+	fs                 boshsys.FileSystem
+	ipResolver         ip.IPResolver
+	addressBroadcaster arp.AddressBroadcaster
 }
 
 func NewUbuntuNetManager(
 	fs boshsys.FileSystem,
 	cmdRunner boshsys.CmdRunner,
-	arpWaitInterval time.Duration,
+	ipResolver ip.IPResolver,
+	addressBroadcaster arp.AddressBroadcaster,
 ) (net ubuntu) {
-	net.arpWaitInterval = arpWaitInterval
 	net.cmdRunner = cmdRunner
 	net.fs = fs
+	net.ipResolver = ipResolver
+	net.addressBroadcaster = addressBroadcaster
 	return
 }
 
-func (net ubuntu) getDNSServers(networks boshsettings.Networks) []string {
-	var dnsServers []string
-	dnsNetwork, found := networks.DefaultNetworkFor("dns")
-	if found {
-		for i := len(dnsNetwork.DNS) - 1; i >= 0; i-- {
-			dnsServers = append(dnsServers, dnsNetwork.DNS[i])
-		}
-	}
-	return dnsServers
-}
-
 func (net ubuntu) SetupDhcp(networks boshsettings.Networks) error {
-	dnsServers := net.getDNSServers(networks)
-
-	buffer := bytes.NewBuffer([]byte{})
-	t := template.Must(template.New("dhcp-config").Parse(ubuntuDHCPConfigTemplate))
+	dnsServersArg := dnsConfigArg{DNSServers: getDNSServers(networks), HasIPv6: hasIPv6Network(networks)}
 
-	err := t.Execute(buffer, dnsConfigArg{dnsServers})
+	buffer, err := renderTemplate("dhcp-config", ubuntuDHCPConfigTemplate, dnsServersArg)
 	if err != nil {
-		return bosherr.WrapError(err, "Generating config from template")
+		return err
 	}
 
-	written, err := net.fs.ConvergeFileContents("/etc/dhcp3/dhclient.conf", buffer.Bytes())
+	written, err := convergeFile(net.fs, "/etc/dhcp3/dhclient.conf", buffer.Bytes())
 	if err != nil {
-		return bosherr.WrapError(err, "Writing to /etc/dhcp3/dhclient.conf")
+		return err
 	}
 
 	if written {
@@ -78,45 +63,47 @@ request subnet-mask, broadcast-address, time-offset, routers,
 	rfc3442-classless-static-routes, ntp-servers;
 
 {{ range .DNSServers }}prepend domain-name-servers {{ . }};
+{{ end }}{{ if .HasIPv6 }}
+also request dhcp6.name-servers;
 {{ end }}`
 
+const ubuntuInterfacesConfigPath = "/etc/network/interfaces"
+
 func (net ubuntu) SetupManualNetworking(networks boshsettings.Networks) error {
+	snapshot := snapshotNetworkState(net.fs, []string{ubuntuInterfacesConfigPath})
+
 	modifiedNetworks, written, err := net.writeNetworkInterfaces(networks)
 	if err != nil {
 		return bosherr.WrapError(err, "Writing network interfaces")
 	}
 
-	if written {
-		net.restartNetworkingInterfaces(modifiedNetworks)
-	}
-
-	err = net.writeResolvConf(networks)
+	err = writeResolvConf(net.fs, networks)
 	if err != nil {
 		return bosherr.WrapError(err, "Writing resolv.conf")
 	}
 
-	go net.gratuitiousArp(modifiedNetworks)
-
-	return nil
-}
-
-func (net ubuntu) gratuitiousArp(networks []CustomNetwork) {
-	for i := 0; i < 6; i++ {
-		for _, network := range networks {
-			for !net.fs.FileExists(filepath.Join("/sys/class/net", network.Interface)) {
-				time.Sleep(100 * time.Millisecond)
-			}
-
-			net.cmdRunner.RunCommand("arping", "-c", "1", "-U", "-I", network.Interface, network.IP)
-			time.Sleep(net.arpWaitInterval)
+	if written {
+		err = convergeNetworkInterfaces(
+			net.fs,
+			snapshot,
+			net.ipResolver,
+			modifiedNetworks,
+			net.restartNetworkingInterfaces,
+		)
+		if err != nil {
+			return bosherr.WrapError(err, "Converging network interfaces")
 		}
 	}
+
+	go broadcastMACAddresses(net.ipResolver, net.addressBroadcaster, modifiedNetworks)
+
+	return nil
 }
 
 func (net ubuntu) writeNetworkInterfaces(networks boshsettings.Networks) ([]CustomNetwork, bool, error) {
 	var modifiedNetworks []CustomNetwork
 
-	macAddresses, err := net.detectMacAddresses()
+	macAddresses, err := detectMacAddresses(net.fs)
 	if err != nil {
 		return modifiedNetworks, false, bosherr.WrapError(err, "Detecting mac addresses")
 	}
@@ -128,26 +115,42 @@ func (net ubuntu) writeNetworkInterfaces(networks boshsettings.Networks) ([]Cust
 		}
 
 		newNet := CustomNetwork{
-			aNet,
-			macAddresses[aNet.Mac],
-			network,
-			broadcast,
-			true,
+			Network:           aNet,
+			Interface:         macAddresses[aNet.Mac],
+			NetworkIP:         network,
+			Broadcast:         broadcast,
+			HasDefaultGateway: true,
+		}
+
+		if aNet.IP6 != "" {
+			ip6Address, ip6Prefix, err := splitIPv6CIDR(aNet.IP6)
+			if err != nil {
+				return modifiedNetworks, false, bosherr.WrapError(err, "Parsing IPv6 CIDR")
+			}
+
+			ip6Network, err := calculateIPv6Network(ip6Address, ip6Prefix)
+			if err != nil {
+				return modifiedNetworks, false, bosherr.WrapError(err, "Calculating IPv6 network")
+			}
+
+			newNet.HasIPv6 = true
+			newNet.IPv6Address = ip6Address
+			newNet.IPv6Prefix = ip6Prefix
+			newNet.IPv6NetworkIP = ip6Network
+			newNet.HasDefaultGateway6 = aNet.Gateway6 != ""
 		}
+
 		modifiedNetworks = append(modifiedNetworks, newNet)
 	}
 
-	buffer := bytes.NewBuffer([]byte{})
-	t := template.Must(template.New("network-interfaces").Parse(ubuntuNetworkInterfacesTemplate))
-
-	err = t.Execute(buffer, modifiedNetworks)
+	buffer, err := renderTemplate("network-interfaces", ubuntuNetworkInterfacesTemplate, modifiedNetworks)
 	if err != nil {
-		return modifiedNetworks, false, bosherr.WrapError(err, "Generating config from template")
+		return modifiedNetworks, false, err
 	}
 
-	written, err := net.fs.ConvergeFileContents("/etc/network/interfaces", buffer.Bytes())
+	written, err := convergeFile(net.fs, ubuntuInterfacesConfigPath, buffer.Bytes())
 	if err != nil {
-		return modifiedNetworks, false, bosherr.WrapError(err, "Writing to /etc/network/interfaces")
+		return modifiedNetworks, false, err
 	}
 
 	return modifiedNetworks, written, nil
@@ -163,54 +166,16 @@ iface {{ .Interface }} inet static
     network {{ .NetworkIP }}
     netmask {{ .Netmask }}
     broadcast {{ .Broadcast }}
-{{ if .HasDefaultGateway }}    gateway {{ .Gateway }}{{ end }}{{ end }}`
-
-func (net ubuntu) writeResolvConf(networks boshsettings.Networks) error {
-	buffer := bytes.NewBuffer([]byte{})
-	t := template.Must(template.New("resolv-conf").Parse(ubuntuResolvConfTemplate))
-
-	dnsServers := net.getDNSServers(networks)
-	dnsServersArg := dnsConfigArg{dnsServers}
-	err := t.Execute(buffer, dnsServersArg)
-	if err != nil {
-		return bosherr.WrapError(err, "Generating config from template")
-	}
-
-	err = net.fs.WriteFile("/etc/resolv.conf", buffer.Bytes())
-	if err != nil {
-		return bosherr.WrapError(err, "Writing to /etc/resolv.conf")
-	}
-
-	return nil
-}
-
-const ubuntuResolvConfTemplate = `# Generated by bosh-agent
-{{ range .DNSServers }}nameserver {{ . }}
-{{ end }}`
-
-func (net ubuntu) detectMacAddresses() (map[string]string, error) {
-	addresses := map[string]string{}
-
-	filePaths, err := net.fs.Glob("/sys/class/net/*")
-	if err != nil {
-		return addresses, bosherr.WrapError(err, "Getting file list from /sys/class/net")
-	}
-
-	var macAddress string
-	for _, filePath := range filePaths {
-		macAddress, err = net.fs.ReadFileString(filepath.Join(filePath, "address"))
-		if err != nil {
-			return addresses, bosherr.WrapError(err, "Reading mac address from file")
-		}
-
-		macAddress = strings.Trim(macAddress, "\n")
-
-		interfaceName := filepath.Base(filePath)
-		addresses[macAddress] = interfaceName
-	}
-
-	return addresses, nil
-}
+{{ if .HasDefaultGateway }}    gateway {{ .Gateway }}
+{{ end }}{{ if .Mtu }}    mtu {{ .Mtu }}
+{{ end }}{{ range .Routes }}    post-up ip route add {{ .Destination }} via {{ .Gateway }}
+{{ end }}{{ if .HasIPv6 }}
+iface {{ .Interface }} inet6 static
+    address {{ .IPv6Address }}
+    network {{ .IPv6NetworkIP }}
+    netmask {{ .IPv6Prefix }}
+{{ if .HasDefaultGateway6 }}    gateway {{ .Gateway6 }}
+{{ end }}{{ end }}{{ end }}`
 
 func (net ubuntu) restartNetworkingInterfaces(networks []CustomNetwork) {
 	for _, network := range networks {
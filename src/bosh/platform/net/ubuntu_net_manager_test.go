@@ -0,0 +1,77 @@
+package net
+
+import (
+	"strings"
+	"testing"
+
+	boshsettings "bosh/settings"
+	boshsysfakes "bosh/system/fakes"
+)
+
+func TestUbuntuWriteNetworkInterfacesRendersMtuRoutesAndIPv6(t *testing.T) {
+	fs := boshsysfakes.NewFakeFileSystem()
+	fs.GlobPaths = []string{"/sys/class/net/eth0"}
+	fs.SetFileContents("/sys/class/net/eth0/address", "aa:bb:cc:dd:ee:ff")
+
+	cmdRunner := boshsysfakes.NewFakeCmdRunner()
+	net := NewUbuntuNetManager(fs, cmdRunner, nil, nil)
+
+	networks := boshsettings.Networks{
+		{
+			IP: "10.0.0.5", Netmask: "255.255.255.0", Gateway: "10.0.0.1", Mac: "aa:bb:cc:dd:ee:ff",
+			Mtu:      "1400",
+			Routes:   []boshsettings.Route{{Destination: "172.16.0.0/12", Gateway: "10.0.0.254"}},
+			IP6:      "2001:db8::2/64",
+			Gateway6: "2001:db8::1",
+		},
+	}
+
+	modifiedNetworks, written, err := net.writeNetworkInterfaces(networks)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+	if !written {
+		t.Fatal("expected writeNetworkInterfaces to report changes written")
+	}
+	if len(modifiedNetworks) != 1 {
+		t.Fatalf("expected 1 modified network, got %d", len(modifiedNetworks))
+	}
+
+	interfaces := fs.GetFileContents(ubuntuInterfacesConfigPath)
+
+	for _, expected := range []string{
+		"    mtu 1400",
+		"    post-up ip route add 172.16.0.0/12 via 10.0.0.254",
+		"iface eth0 inet6 static",
+		"    address 2001:db8::2",
+		"    netmask 64",
+		"    gateway 2001:db8::1",
+	} {
+		if !strings.Contains(interfaces, expected) {
+			t.Errorf("expected rendered interfaces file to contain %q, got:\n%s", expected, interfaces)
+		}
+	}
+}
+
+func TestUbuntuWriteNetworkInterfacesOmitsIPv6SectionWithoutIP6(t *testing.T) {
+	fs := boshsysfakes.NewFakeFileSystem()
+	fs.GlobPaths = []string{"/sys/class/net/eth0"}
+	fs.SetFileContents("/sys/class/net/eth0/address", "aa:bb:cc:dd:ee:ff")
+
+	cmdRunner := boshsysfakes.NewFakeCmdRunner()
+	net := NewUbuntuNetManager(fs, cmdRunner, nil, nil)
+
+	networks := boshsettings.Networks{
+		{IP: "10.0.0.5", Netmask: "255.255.255.0", Gateway: "10.0.0.1", Mac: "aa:bb:cc:dd:ee:ff"},
+	}
+
+	_, _, err := net.writeNetworkInterfaces(networks)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	interfaces := fs.GetFileContents(ubuntuInterfacesConfigPath)
+	if strings.Contains(interfaces, "inet6") {
+		t.Errorf("expected no inet6 section without an IP6 address, got:\n%s", interfaces)
+	}
+}
@@ -0,0 +1,207 @@
+package net
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/masterzen/winrm"
+
+	bosherr "bosh/errors"
+	boshsettings "bosh/settings"
+)
+
+type windows struct {
+	client *winrm.Client
+}
+
+// NewWindowsNetManager returns a NetManager that configures networking on a
+// remote Windows machine over the given WinRM client.
+func NewWindowsNetManager(client *winrm.Client) (net windows) {
+	net.client = client
+	return
+}
+
+func (net windows) SetupDhcp(networks boshsettings.Networks) error {
+	macAddresses, err := net.detectMacAddresses()
+	if err != nil {
+		return bosherr.WrapError(err, "Detecting mac addresses")
+	}
+
+	dhcpArg := windowsDhcpConfigArg{
+		Interfaces: interfaceNamesByMac(networks, macAddresses),
+		DNSServers: getDNSServers(networks),
+	}
+
+	buffer, err := renderTemplate("dhcp-config", windowsDhcpConfigTemplate, dhcpArg)
+	if err != nil {
+		return err
+	}
+
+	_, err = net.runPowershell(buffer.String())
+	if err != nil {
+		return bosherr.WrapError(err, "Enabling DHCP")
+	}
+
+	return nil
+}
+
+type windowsDhcpConfigArg struct {
+	Interfaces []string
+	DNSServers []string
+}
+
+const windowsDhcpConfigTemplate = `# Generated by bosh-agent
+{{ range .Interfaces }}Set-NetIPInterface -InterfaceAlias "{{ . }}" -Dhcp Enabled
+{{ end }}{{ range .Interfaces }}Set-DnsClientServerAddress -InterfaceAlias "{{ . }}" -ResetServerAddresses
+{{ end }}`
+
+func (net windows) SetupManualNetworking(networks boshsettings.Networks) error {
+	macAddresses, err := net.detectMacAddresses()
+	if err != nil {
+		return bosherr.WrapError(err, "Detecting mac addresses")
+	}
+
+	modifiedNetworks, err := buildCustomNetworks(networks, macAddresses)
+	if err != nil {
+		return bosherr.WrapError(err, "Building custom networks")
+	}
+
+	networkArg := windowsNetworkConfigArg{
+		Networks:   modifiedNetworks,
+		DNSServers: getDNSServers(networks),
+	}
+
+	buffer, err := renderTemplate("network-config", windowsNetworkConfigTemplate, networkArg)
+	if err != nil {
+		return err
+	}
+
+	_, err = net.runPowershell(buffer.String())
+	if err != nil {
+		return bosherr.WrapError(err, "Configuring network interfaces")
+	}
+
+	go net.broadcastMACAddresses(modifiedNetworks)
+
+	return nil
+}
+
+type windowsNetworkConfigArg struct {
+	Networks   []CustomNetwork
+	DNSServers []string
+}
+
+const windowsNetworkConfigTemplate = `# Generated by bosh-agent
+{{ $dns := .DNSServers }}{{ range .Networks }}Remove-NetIPAddress -InterfaceAlias "{{ .Interface }}" -Confirm:$false -ErrorAction SilentlyContinue
+New-NetIPAddress -InterfaceAlias "{{ .Interface }}" -IPAddress {{ .IP }} -PrefixLength {{ .PrefixLength }}{{ if .HasDefaultGateway }} -DefaultGateway {{ .Gateway }}{{ end }}
+Set-DnsClientServerAddress -InterfaceAlias "{{ .Interface }}" -ServerAddresses {{ range $dns }}{{ . }},{{ end }}
+{{ end }}`
+
+func buildCustomNetworks(networks boshsettings.Networks, macAddresses map[string]string) ([]CustomNetwork, error) {
+	var modifiedNetworks []CustomNetwork
+
+	for _, aNet := range networks {
+		prefixLen, err := netmaskToPrefixLen(aNet.Netmask)
+		if err != nil {
+			return modifiedNetworks, bosherr.WrapError(err, "Converting netmask to prefix length")
+		}
+
+		newNet := CustomNetwork{
+			Network:           aNet,
+			Interface:         macAddresses[aNet.Mac],
+			PrefixLength:      strconv.Itoa(prefixLen),
+			HasDefaultGateway: aNet.Gateway != "",
+		}
+		modifiedNetworks = append(modifiedNetworks, newNet)
+	}
+
+	return modifiedNetworks, nil
+}
+
+// detectMacAddresses asks Windows for its adapters instead of walking
+// /sys/class/net, since there's no local filesystem to inspect over WinRM.
+func (net windows) detectMacAddresses() (map[string]string, error) {
+	addresses := map[string]string{}
+
+	stdout, err := net.runPowershell(
+		`Get-NetAdapter | Select MacAddress,Name | ConvertTo-Csv -NoTypeInformation`,
+	)
+	if err != nil {
+		return addresses, bosherr.WrapError(err, "Running Get-NetAdapter")
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout), "\r\n")
+	for _, line := range lines[1:] {
+		fields := strings.SplitN(strings.Trim(line, `"`), `","`, 2)
+		if len(fields) != 2 {
+			continue
+		}
+
+		macAddress := strings.ToLower(strings.Replace(fields[0], "-", ":", -1))
+		addresses[macAddress] = fields[1]
+	}
+
+	return addresses, nil
+}
+
+func interfaceNamesByMac(networks boshsettings.Networks, macAddresses map[string]string) []string {
+	var interfaces []string
+	for _, aNet := range networks {
+		if iface, found := macAddresses[aNet.Mac]; found {
+			interfaces = append(interfaces, iface)
+		}
+	}
+	return interfaces
+}
+
+// broadcastMACAddresses replaces the arping-based gratuitous ARP used on
+// Linux with Windows' own ARP cache flush and announce equivalents.
+func (net windows) broadcastMACAddresses(networks []CustomNetwork) {
+	for _, network := range networks {
+		net.runPowershell(fmt.Sprintf(
+			`arp -d * ; Send-ArpRequest -InterfaceAlias "%s" -IPAddress %s`,
+			network.Interface,
+			network.IP,
+		))
+	}
+}
+
+func (net windows) runPowershell(cmd string) (string, error) {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	exitCode, err := net.client.Run(winrm.Powershell(cmd), stdout, stderr)
+	if err != nil {
+		return "", bosherr.WrapError(err, "Running powershell command")
+	}
+
+	if exitCode != 0 {
+		return "", bosherr.New("Powershell command exited %d: %s", exitCode, stderr.String())
+	}
+
+	return stdout.String(), nil
+}
+
+func netmaskToPrefixLen(netmask string) (int, error) {
+	octets := strings.Split(netmask, ".")
+	if len(octets) != 4 {
+		return 0, bosherr.New("Invalid netmask %s", netmask)
+	}
+
+	prefixLen := 0
+	for _, octet := range octets {
+		value, err := strconv.Atoi(octet)
+		if err != nil {
+			return 0, bosherr.WrapError(err, "Parsing netmask octet")
+		}
+
+		for value > 0 {
+			prefixLen += value & 1
+			value >>= 1
+		}
+	}
+
+	return prefixLen, nil
+}
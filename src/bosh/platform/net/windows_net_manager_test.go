@@ -0,0 +1,71 @@
+package net
+
+import (
+	"testing"
+
+	boshsettings "bosh/settings"
+)
+
+func TestNetmaskToPrefixLen(t *testing.T) {
+	prefixLen, err := netmaskToPrefixLen("255.255.255.0")
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+
+	if prefixLen != 24 {
+		t.Errorf("expected prefix length 24, got %d", prefixLen)
+	}
+}
+
+func TestNetmaskToPrefixLenInvalid(t *testing.T) {
+	_, err := netmaskToPrefixLen("not-a-netmask")
+	if err == nil {
+		t.Fatal("expected an error for an invalid netmask")
+	}
+}
+
+func TestBuildCustomNetworksSetsHasDefaultGatewayOnlyWhenGatewayIsSet(t *testing.T) {
+	networks := boshsettings.Networks{
+		{IP: "10.0.0.5", Netmask: "255.255.255.0", Gateway: "10.0.0.1", Mac: "aa:bb:cc:dd:ee:ff"},
+		{IP: "10.0.0.6", Netmask: "255.255.255.0", Mac: "11:22:33:44:55:66"},
+	}
+	macAddresses := map[string]string{
+		"aa:bb:cc:dd:ee:ff": "Ethernet0",
+		"11:22:33:44:55:66": "Ethernet1",
+	}
+
+	modifiedNetworks, err := buildCustomNetworks(networks, macAddresses)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err.Error())
+	}
+	if len(modifiedNetworks) != 2 {
+		t.Fatalf("expected 2 networks, got %d", len(modifiedNetworks))
+	}
+
+	if !modifiedNetworks[0].HasDefaultGateway {
+		t.Error("expected HasDefaultGateway to be true when Gateway is set")
+	}
+	if modifiedNetworks[0].PrefixLength != "24" {
+		t.Errorf("expected prefix length 24, got %s", modifiedNetworks[0].PrefixLength)
+	}
+
+	if modifiedNetworks[1].HasDefaultGateway {
+		t.Error("expected HasDefaultGateway to be false when Gateway is not set")
+	}
+}
+
+func TestInterfaceNamesByMac(t *testing.T) {
+	networks := boshsettings.Networks{
+		{Mac: "aa:bb:cc:dd:ee:ff"},
+		{Mac: "11:22:33:44:55:66"},
+	}
+	macAddresses := map[string]string{
+		"aa:bb:cc:dd:ee:ff": "Ethernet",
+	}
+
+	interfaces := interfaceNamesByMac(networks, macAddresses)
+
+	if len(interfaces) != 1 || interfaces[0] != "Ethernet" {
+		t.Errorf("expected [Ethernet], got %v", interfaces)
+	}
+}
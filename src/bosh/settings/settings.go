@@ -0,0 +1,60 @@
+package settings
+
+// Route is a static route to converge alongside a network's default gateway.
+type Route struct {
+	Destination string
+	Gateway     string
+}
+
+// Network describes one of the VM's networks, as provided by the
+// infrastructure's agent settings.
+type Network struct {
+	Type    string
+	IP      string
+	Netmask string
+	Gateway string
+	Mac     string
+
+	// IP6 and Gateway6 carry the network's IPv6 configuration: IP6 as an
+	// "address/prefix" CIDR string, Gateway6 as a plain address. Both are
+	// empty for IPv4-only networks.
+	IP6      string
+	Gateway6 string
+
+	Mtu    string
+	Routes []Route
+
+	DNS     []string
+	Default []string
+}
+
+// IsDefaultFor returns whether this network should be used for the given
+// default category (e.g. "dns", "gateway").
+func (n Network) IsDefaultFor(category string) bool {
+	for _, defaultFor := range n.Default {
+		if defaultFor == category {
+			return true
+		}
+	}
+	return false
+}
+
+// Networks is the full set of networks assigned to the VM.
+type Networks []Network
+
+// DefaultNetworkFor returns the network designated as default for the given
+// category (e.g. "dns", "gateway"). If no network is explicitly marked, and
+// there is exactly one network, that network is returned.
+func (networks Networks) DefaultNetworkFor(category string) (Network, bool) {
+	if len(networks) == 1 {
+		return networks[0], true
+	}
+
+	for _, network := range networks {
+		if network.IsDefaultFor(category) {
+			return network, true
+		}
+	}
+
+	return Network{}, false
+}
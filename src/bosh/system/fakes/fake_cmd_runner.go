@@ -0,0 +1,30 @@
+package fakes
+
+type FakeCmdResult struct {
+	Stdout     string
+	Stderr     string
+	ExitStatus int
+	Error      error
+}
+
+type FakeCmdRunner struct {
+	RunCommands [][]string
+	CmdResults  []FakeCmdResult
+}
+
+func NewFakeCmdRunner() *FakeCmdRunner {
+	return &FakeCmdRunner{}
+}
+
+func (r *FakeCmdRunner) RunCommand(cmd string, args ...string) (string, string, int, error) {
+	fullCmd := append([]string{cmd}, args...)
+	r.RunCommands = append(r.RunCommands, fullCmd)
+
+	index := len(r.RunCommands) - 1
+	if index < len(r.CmdResults) {
+		result := r.CmdResults[index]
+		return result.Stdout, result.Stderr, result.ExitStatus, result.Error
+	}
+
+	return "", "", 0, nil
+}
@@ -0,0 +1,68 @@
+package fakes
+
+import bosherr "bosh/errors"
+
+type FakeFileSystem struct {
+	files map[string]string
+
+	GlobPaths []string
+	GlobErr   error
+
+	WriteFileError error
+
+	ConvergeFileContentsWritten bool
+	ConvergeFileContentsError   error
+}
+
+func NewFakeFileSystem() *FakeFileSystem {
+	return &FakeFileSystem{files: map[string]string{}}
+}
+
+func (fs *FakeFileSystem) Glob(pattern string) ([]string, error) {
+	return fs.GlobPaths, fs.GlobErr
+}
+
+func (fs *FakeFileSystem) ReadFileString(path string) (string, error) {
+	contents, found := fs.files[path]
+	if !found {
+		return "", bosherr.New("File %s not found", path)
+	}
+	return contents, nil
+}
+
+func (fs *FakeFileSystem) WriteFile(path string, content []byte) error {
+	if fs.WriteFileError != nil {
+		return fs.WriteFileError
+	}
+
+	fs.files[path] = string(content)
+	return nil
+}
+
+func (fs *FakeFileSystem) FileExists(path string) bool {
+	_, found := fs.files[path]
+	return found
+}
+
+func (fs *FakeFileSystem) ConvergeFileContents(path string, content []byte) (bool, error) {
+	if fs.ConvergeFileContentsError != nil {
+		return false, fs.ConvergeFileContentsError
+	}
+
+	if existing, found := fs.files[path]; found && existing == string(content) {
+		return false, nil
+	}
+
+	fs.files[path] = string(content)
+	return true, nil
+}
+
+// GetFileContents returns what was last written to path, for assertions.
+func (fs *FakeFileSystem) GetFileContents(path string) string {
+	return fs.files[path]
+}
+
+// SetFileContents seeds path with contents, as if written by a prior run.
+func (fs *FakeFileSystem) SetFileContents(path string, contents string) {
+	fs.files[path] = contents
+}
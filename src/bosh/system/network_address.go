@@ -0,0 +1,31 @@
+package system
+
+import (
+	"net"
+
+	bosherr "bosh/errors"
+)
+
+// CalculateNetworkAndBroadcast derives the IPv4 network and broadcast
+// addresses for a dotted-decimal address/netmask pair.
+func CalculateNetworkAndBroadcast(ipAddress string, netmask string) (string, string, error) {
+	ip := net.ParseIP(ipAddress).To4()
+	if ip == nil {
+		return "", "", bosherr.New("Invalid IPv4 address %s", ipAddress)
+	}
+
+	mask := net.ParseIP(netmask).To4()
+	if mask == nil {
+		return "", "", bosherr.New("Invalid IPv4 netmask %s", netmask)
+	}
+
+	network := make(net.IP, len(ip))
+	broadcast := make(net.IP, len(ip))
+
+	for i := range ip {
+		network[i] = ip[i] & mask[i]
+		broadcast[i] = ip[i] | ^mask[i]
+	}
+
+	return network.String(), broadcast.String(), nil
+}
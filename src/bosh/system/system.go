@@ -0,0 +1,20 @@
+package system
+
+// FileSystem is the subset of filesystem operations the agent needs,
+// abstracted so platform code can be tested against fakes.
+type FileSystem interface {
+	Glob(pattern string) ([]string, error)
+	ReadFileString(path string) (string, error)
+	WriteFile(path string, content []byte) error
+	FileExists(path string) bool
+
+	// ConvergeFileContents writes content to path only if it differs from
+	// what's already there, returning whether a write happened.
+	ConvergeFileContents(path string, content []byte) (bool, error)
+}
+
+// CmdRunner shells out to external commands, abstracted so platform code can
+// be tested against fakes.
+type CmdRunner interface {
+	RunCommand(cmd string, args ...string) (stdout, stderr string, exitStatus int, err error)
+}